@@ -23,22 +23,84 @@ type Verifier struct {
 	ghClient github.Client
 	// pluginConfig is used to check whether a repository allows approving reviews as LGTM
 	pluginConfig *plugins.Configuration
+	// policy declares the per-org/repo/branch verification requirements to evaluate bugs
+	// against. A nil policy preserves the hardcoded ON_QA/VERIFIED/qe-approved behavior.
+	policy *VerifierPolicy
+	// optOutStore tracks which QA contacts have opted out of verification pings. May be nil,
+	// in which case no QA contact is ever considered opted out.
+	optOutStore QAOptOutStore
+	// fallbackQAContacts maps a bugzilla component to the email of the contact to address
+	// instead, for bugs whose QA contact has opted out. The fallback contact's own opt-out
+	// state is checked the same way as any other QA contact's.
+	fallbackQAContacts map[string]string
+	// qaContactGitHubLogins maps a bugzilla QA contact's email (lower-cased) to the GitHub
+	// login that contact reviews under. Bugzilla and GitHub accounts live in unrelated
+	// namespaces, so this mapping is the only way changeset evaluation can recognize that an
+	// approving review came from a bug's QA contact. May be nil/empty, in which case no
+	// changeset's QAApprovalSatisfiesSet ever matches.
+	qaContactGitHubLogins map[string]string
 }
 
-// NewVerifier returns a Verifier configured with the provided github and bugzilla clients and the provided pluginConfig
-func NewVerifier(bzClient bugzilla.Client, ghClient github.Client, pluginConfig *plugins.Configuration) *Verifier {
+// NewVerifier returns a Verifier configured with the provided github and bugzilla clients, the
+// provided pluginConfig, and the provided verification policy. policy may be nil, in which case
+// the verifier falls back to requiring ON_QA status, the `qe-approved` label, and moves bugs to
+// VERIFIED. optOutStore and fallbackQAContacts may both be nil/empty, in which case QA contacts
+// are always addressed by name. qaContactGitHubLogins maps a QA contact's bugzilla email
+// (lower-cased) to their GitHub login; it may be nil/empty, in which case a ChangesetPolicy's
+// QAApprovalSatisfiesSet never matches.
+func NewVerifier(bzClient bugzilla.Client, ghClient github.Client, pluginConfig *plugins.Configuration, policy *VerifierPolicy, optOutStore QAOptOutStore, fallbackQAContacts map[string]string, qaContactGitHubLogins map[string]string) *Verifier {
 	return &Verifier{
-		bzClient:     bzClient,
-		ghClient:     ghClient,
-		pluginConfig: pluginConfig,
+		bzClient:              bzClient,
+		ghClient:              ghClient,
+		pluginConfig:          pluginConfig,
+		policy:                policy,
+		optOutStore:           optOutStore,
+		fallbackQAContacts:    fallbackQAContacts,
+		qaContactGitHubLogins: qaContactGitHubLogins,
 	}
 }
 
-// pr contains the org, repo, and pr number for a pr
-type pr struct {
-	org   string
-	repo  string
-	prNum int
+// qaContactGitHubLogin returns the GitHub login bug's QA contact reviews under, as configured
+// in qaContactGitHubLogins, and whether one is known at all.
+func (c *Verifier) qaContactGitHubLogin(bug *bugzilla.Bug) (string, bool) {
+	if bug.QAContactDetail == nil || len(c.qaContactGitHubLogins) == 0 {
+		return "", false
+	}
+	login, ok := c.qaContactGitHubLogins[strings.ToLower(bug.QAContactDetail.Email)]
+	return login, ok
+}
+
+// PR identifies a GitHub pull request linked to a bugzilla bug.
+type PR struct {
+	Org  string
+	Repo string
+	Num  int
+}
+
+// BugPRs pairs a bugzilla bug with the GitHub PRs linked to it, along with the ID of the
+// topmost bug in its clone chain (see GetRoot), for consumers such as the release notes
+// composer that need to collapse a fix tracked against several releases into one entry.
+type BugPRs struct {
+	Bug    *bugzilla.Bug
+	PRs    []PR
+	RootID int
+}
+
+// policyFor resolves the effective RepoPolicy for extPR, looking up its branch via the github
+// client only when policy has a branch-specific entry for extPR's org/repo.
+func (c *Verifier) policyFor(extPR PR) (RepoPolicy, error) {
+	if c.policy == nil {
+		return defaultRepoPolicy(), nil
+	}
+	var branch string
+	if c.policy.hasBranchPolicy(extPR.Org, extPR.Repo) {
+		ghPR, err := c.ghClient.GetPullRequest(extPR.Org, extPR.Repo, extPR.Num)
+		if err != nil {
+			return RepoPolicy{}, fmt.Errorf("Unable to get github pull %s/%s#%d: %v", extPR.Org, extPR.Repo, extPR.Num, err)
+		}
+		branch = ghPR.Base.Ref
+	}
+	return c.policy.resolve(extPR.Org, extPR.Repo, branch), nil
 }
 
 // VerifyBugs takes a list of bugzilla bug IDs and for each bug changes the bug status to VERIFIED if bug was reviewed and
@@ -49,104 +111,179 @@ func (c *Verifier) VerifyBugs(bugs []int, tagName string) []error {
 		return []error{fmt.Errorf("failed to parse tag `%s` semver: %w", tagName, err)}
 	}
 	tagRelease := releasecontroller.SemverToMajorMinor(tagSemVer)
-	bzPRs, errs := getPRs(bugs, c.bzClient)
-	for bugID, extPRs := range bzPRs {
-		bug, err := c.bzClient.GetBug(bugID)
+	bzPRs, errs := c.GetPRs(bugs)
+	for _, bugPRs := range bzPRs {
+		bug, extPRs := bugPRs.Bug, bugPRs.PRs
+		bugRelease, err := majorMinorRelease(bug)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("Unable to get bugzilla number %d: %v", bugID, err))
+			errs = append(errs, err)
 			continue
 		}
-		// bugzilla usually denotes unset target releases with `---`
-		if len(bug.TargetRelease) == 0 || bug.TargetRelease[0] == "---" {
+		if bugRelease == "" {
 			klog.Warningf("Bug %d does not have a target release", bug.ID)
 			continue
 		}
-		// the format for target release is always `int.int.{0,z}`
-		bugSplitVer := strings.Split(bug.TargetRelease[0], ".")
-		if len(bugSplitVer) < 2 {
-			errs = append(errs, fmt.Errorf("Bug %d: length of target release `%s` after split by `.` is less than 2", bug.ID, bug.TargetRelease[0]))
-			continue
-		}
-		bugRelease := fmt.Sprintf("%s.%s", bugSplitVer[0], bugSplitVer[1])
 		if bugRelease != tagRelease {
-			// bugfix included in different release than target; ignore
-			klog.Infof("Bug %d is in different release (%s) than tag %s", bug.ID, bugRelease, tagName)
+			// the fix may already be tracked against a different release via a clone;
+			// look for a sibling clone targeting this release and verify that one instead
+			clones, err := c.GetAllClones(bug)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Unable to get clones of bugzilla bug %d: %v", bug.ID, err))
+				continue
+			}
+			var cloneMatch *bugzilla.Bug
+			for _, clone := range clones {
+				cloneRelease, err := majorMinorRelease(clone)
+				if err != nil || cloneRelease != tagRelease {
+					continue
+				}
+				cloneMatch = clone
+				break
+			}
+			if cloneMatch == nil {
+				// bugfix included in different release than target; ignore
+				klog.Infof("Bug %d is in different release (%s) than tag %s", bug.ID, bugRelease, tagName)
+				continue
+			}
+			klog.V(4).Infof("Bug %d targets release %s; verifying clone %d which targets %s instead", bug.ID, bugRelease, cloneMatch.ID, tagRelease)
+			bug = cloneMatch
+		}
+		bugPolicy := defaultRepoPolicy()
+		if len(extPRs) > 0 {
+			resolved, err := c.policyFor(extPRs[0])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			bugPolicy = resolved
+		}
+		if bugPolicy.targetReleaseRegexp != nil && !bugPolicy.targetReleaseRegexp.MatchString(bug.TargetRelease[0]) {
+			klog.Infof("Bug %d target release %q does not match required pattern for its policy", bug.ID, bug.TargetRelease[0])
 			continue
 		}
 		var success bool
 		message := fmt.Sprintf("Bugfix included in accepted release %s", tagName)
-		var unlabeledPRs []pr
+		var unlabeledPRs []PR
 		var bugErrs []error
-		if bug.Status != "ON_QA" {
-			// In case bug has already been moved to VERIFIED, completely ignore
-			if bug.Status == "VERIFIED" {
-				klog.V(4).Infof("Bug %d already in VERIFIED status", bug.ID)
+		if bug.Status != bugPolicy.IncomingStatus {
+			// In case bug has already reached the target status, completely ignore
+			if bug.Status == bugPolicy.TargetStatus {
+				klog.V(4).Infof("Bug %d already in %s status", bug.ID, bugPolicy.TargetStatus)
 				continue
 			} else {
-				bugErrs = append(bugErrs, fmt.Errorf("Bug is not in ON_QA status"))
+				bugErrs = append(bugErrs, fmt.Errorf("Bug is not in %s status", bugPolicy.IncomingStatus))
+			}
+		} else if bugPolicy.Changeset != nil {
+			// evaluate the bug's PRs as a single changeset rather than requiring each one to
+			// independently carry its own QA approval
+			verified, changesetErrs := c.evaluateChangeset(bug, extPRs, *bugPolicy.Changeset, bugPolicy.ForbiddenLabels)
+			errs = append(errs, changesetErrs...)
+			bugErrs = append(bugErrs, changesetErrs...)
+			if !verified {
+				bugErrs = append(bugErrs, fmt.Errorf("changeset does not meet the required approval quorum"))
 			}
 		} else {
 			for _, extPR := range extPRs {
-				labels, err := c.ghClient.GetIssueLabels(extPR.org, extPR.repo, extPR.prNum)
+				prPolicy, err := c.policyFor(extPR)
+				if err != nil {
+					errs = append(errs, err)
+					bugErrs = append(bugErrs, err)
+					continue
+				}
+				labels, err := c.ghClient.GetIssueLabels(extPR.Org, extPR.Repo, extPR.Num)
 				if err != nil {
-					newErr := fmt.Errorf("Unable to get labels for github pull %s/%s#%d: %v", extPR.org, extPR.repo, extPR.prNum, err)
+					newErr := fmt.Errorf("Unable to get labels for github pull %s/%s#%d: %v", extPR.Org, extPR.Repo, extPR.Num, err)
 					errs = append(errs, newErr)
 					bugErrs = append(bugErrs, newErr)
+					continue
 				}
-				var hasLabel bool
+				labelSet := make(map[string]bool, len(labels))
 				for _, label := range labels {
-					if label.Name == "qe-approved" {
-						hasLabel = true
+					labelSet[label.Name] = true
+				}
+				var forbidden string
+				for _, bad := range prPolicy.ForbiddenLabels {
+					if labelSet[bad] {
+						forbidden = bad
+						break
+					}
+				}
+				if forbidden != "" {
+					bugErrs = append(bugErrs, fmt.Errorf("PR %s/%s#%d carries forbidden label %q", extPR.Org, extPR.Repo, extPR.Num, forbidden))
+					continue
+				}
+				hasAllRequiredLabels := true
+				for _, required := range prPolicy.RequiredLabels {
+					if !labelSet[required] {
+						hasAllRequiredLabels = false
 						break
 					}
 				}
-				if !hasLabel {
+				if !hasAllRequiredLabels {
 					unlabeledPRs = append(unlabeledPRs, extPR)
+					continue
+				}
+				if prPolicy.RequireMerged {
+					ghPR, err := c.ghClient.GetPullRequest(extPR.Org, extPR.Repo, extPR.Num)
+					if err != nil {
+						newErr := fmt.Errorf("Unable to get github pull %s/%s#%d: %v", extPR.Org, extPR.Repo, extPR.Num, err)
+						errs = append(errs, newErr)
+						bugErrs = append(bugErrs, newErr)
+						continue
+					}
+					if !ghPR.Merged {
+						bugErrs = append(bugErrs, fmt.Errorf("PR %s/%s#%d is not merged", extPR.Org, extPR.Repo, extPR.Num))
+					}
 				}
 			}
 		}
 		if len(unlabeledPRs) > 0 || len(bugErrs) > 0 {
-			message = fmt.Sprintf("%s\nBug will not be automatically moved to VERIFIED for the following reasons:", message)
+			message = fmt.Sprintf("%s\nBug will not be automatically moved to %s for the following reasons:", message, bugPolicy.TargetStatus)
 			for _, extPR := range unlabeledPRs {
-				message = fmt.Sprintf("%s\n- PR %s/%s#%d not approved by QA contact", message, extPR.org, extPR.repo, extPR.prNum)
+				message = fmt.Sprintf("%s\n- PR %s/%s#%d not approved by QA contact", message, extPR.Org, extPR.Repo, extPR.Num)
 			}
 			for _, err := range bugErrs {
 				message = fmt.Sprintf("%s\n- %s", message, err)
 			}
-			message = fmt.Sprintf("%s\n\nThis bug must now be manually moved to VERIFIED", message)
-			// Sometimes the QAContactDetail is nil; if not nil, include name of QA contact in message
-			if bug.QAContactDetail != nil {
-				message = fmt.Sprintf("%s by %s", message, bug.QAContactDetail.Name)
+			message = fmt.Sprintf("%s\n\nThis bug must now be manually moved to %s", message, bugPolicy.TargetStatus)
+			contactName, err := c.resolveQAContact(bug)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if contactName != "" {
+				message = fmt.Sprintf("%s by %s", message, contactName)
 			}
 		} else {
 			success = true
 		}
 		if success {
-			message = fmt.Sprintf("%s\nAll linked GitHub PRs have been approved by a QA contact; updating bug status to VERIFIED", message)
+			message = fmt.Sprintf("%s\nAll linked GitHub PRs have been approved by a QA contact; updating bug status to %s", message, bugPolicy.TargetStatus)
+			if c.policy != nil && c.policy.DryRun {
+				message = fmt.Sprintf("%s\n\n(dry-run: this status change was not actually applied)", message)
+			}
 		}
 		if message != "" {
-			comments, err := c.bzClient.GetComments(bugID)
+			alreadyCommented, err := c.robotCommentExists(bug.ID, message)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("Failed to get comments on bug %d: %v", bug.ID, err))
+				errs = append(errs, err)
 				continue
 			}
-			var alreadyCommented bool
-			for _, comment := range comments {
-				if comment.Text == message && (comment.Creator == "openshift-bugzilla-robot" || comment.Creator == "openshift-bugzilla-robot@redhat.com") {
-					alreadyCommented = true
-					break
-				}
-			}
 			if !alreadyCommented {
-				if _, err := c.bzClient.CreateComment(&bugzilla.CommentCreate{ID: bugID, Comment: message, IsPrivate: true}); err != nil {
+				if _, err := c.bzClient.CreateComment(&bugzilla.CommentCreate{ID: bug.ID, Comment: message, IsPrivate: true}); err != nil {
 					errs = append(errs, fmt.Errorf("Failed to comment on bug %d: %v", bug.ID, err))
 				}
 			}
 		}
 		if success {
-			klog.V(4).Infof("Updating bug %d (current status %s) to VERIFIED status", bug.ID, bug.Status)
-			if err := c.bzClient.UpdateBug(bug.ID, bugzilla.BugUpdate{Status: "VERIFIED"}); err != nil {
+			if c.policy != nil && c.policy.DryRun {
+				klog.V(4).Infof("Dry-run: would update bug %d (current status %s) to %s status", bug.ID, bug.Status, bugPolicy.TargetStatus)
+				continue
+			}
+			klog.V(4).Infof("Updating bug %d (current status %s) to %s status", bug.ID, bug.Status, bugPolicy.TargetStatus)
+			if err := c.bzClient.UpdateBug(bug.ID, bugzilla.BugUpdate{Status: bugPolicy.TargetStatus}); err != nil {
 				errs = append(errs, fmt.Errorf("Failed to update status for bug %d: %v", bug.ID, err))
+			} else if err := c.notifyClonesOfRoot(bug, tagName); err != nil {
+				errs = append(errs, err)
 			}
 		} else {
 			klog.V(4).Infof("Bug %d (current status %s) not approved by QA contact", bug.ID, bug.Status)
@@ -155,13 +292,116 @@ func (c *Verifier) VerifyBugs(bugs []int, tagName string) []error {
 	return errs
 }
 
-// getPRs identifies bugzilla bugs and the associated github PRs fixed in a release from
-// a given buglist generated by `oc adm release info --bugs=git-cache-path --ouptut=name from-tag to-tag`
-func getPRs(input []int, bzClient bugzilla.Client) (map[int][]pr, []error) {
-	bzPRs := make(map[int][]pr)
+// majorMinorRelease returns the `int.int` major/minor release that bug targets, derived
+// from its TargetRelease (whose format is always `int.int.{0,z}`). It returns an empty
+// string if the bug's target release is unset (bugzilla denotes this with `---`).
+func majorMinorRelease(bug *bugzilla.Bug) (string, error) {
+	if len(bug.TargetRelease) == 0 || bug.TargetRelease[0] == "---" {
+		return "", nil
+	}
+	bugSplitVer := strings.Split(bug.TargetRelease[0], ".")
+	if len(bugSplitVer) < 2 {
+		return "", fmt.Errorf("Bug %d: length of target release `%s` after split by `.` is less than 2", bug.ID, bug.TargetRelease[0])
+	}
+	return fmt.Sprintf("%s.%s", bugSplitVer[0], bugSplitVer[1]), nil
+}
+
+// resolveQAContact determines who a manual-verification message should address: the bug's QA
+// contact, or, if that contact has opted out via the QAOptOutStore, the fallback contact
+// configured for the bug's component. It returns an empty name if neither is available or
+// appropriate, and never returns the name or email of an opted-out contact, checking the
+// fallback contact's own opt-out state as well as the original QA contact's.
+func (c *Verifier) resolveQAContact(bug *bugzilla.Bug) (string, error) {
+	if bug.QAContactDetail == nil {
+		return "", nil
+	}
+	if c.optOutStore == nil {
+		return bug.QAContactDetail.Name, nil
+	}
+	optedOut, err := c.optOutStore.IsOptedOut(bug.QAContactDetail.Email)
+	if err != nil {
+		return "", fmt.Errorf("Unable to check QA opt-out status for bug %d: %v", bug.ID, err)
+	}
+	if !optedOut {
+		return bug.QAContactDetail.Name, nil
+	}
+	if len(bug.Component) == 0 {
+		return "", nil
+	}
+	fallbackEmail, ok := c.fallbackQAContacts[bug.Component[0]]
+	if !ok {
+		return "", nil
+	}
+	fallbackOptedOut, err := c.optOutStore.IsOptedOut(fallbackEmail)
+	if err != nil {
+		return "", fmt.Errorf("Unable to check QA opt-out status for bug %d: %v", bug.ID, err)
+	}
+	if fallbackOptedOut {
+		return "", nil
+	}
+	return fallbackEmail, nil
+}
+
+// notifyClonesOfRoot checks whether bug is the root of its clone chain, and if so posts an
+// informational comment on every clone pointing at the release that now contains the fix.
+// This covers z-stream clones, which otherwise never hear about the fix because the PRs
+// are linked on the parent bug only. A bug can be visited more than once in a single
+// VerifyBugs call (once directly, once substituted in via a clone lookup for a release
+// mismatch), so each clone is checked for the identical comment before posting, the same
+// way the main per-bug message above is.
+func (c *Verifier) notifyClonesOfRoot(bug *bugzilla.Bug, tagName string) error {
+	root, err := c.GetRoot(bug)
+	if err != nil {
+		return fmt.Errorf("Unable to determine root bug for bugzilla bug %d: %v", bug.ID, err)
+	}
+	if root.ID != bug.ID {
+		return nil
+	}
+	clones, err := c.GetAllClones(bug)
+	if err != nil {
+		return fmt.Errorf("Unable to get clones of bugzilla bug %d: %v", bug.ID, err)
+	}
+	comment := fmt.Sprintf("Bugfix for this bug's clone tree is included in accepted release %s (verified via bug %d)", tagName, bug.ID)
+	for _, clone := range clones {
+		alreadyCommented, err := c.robotCommentExists(clone.ID, comment)
+		if err != nil {
+			return err
+		}
+		if alreadyCommented {
+			continue
+		}
+		if _, err := c.bzClient.CreateComment(&bugzilla.CommentCreate{ID: clone.ID, Comment: comment, IsPrivate: true}); err != nil {
+			return fmt.Errorf("Failed to comment on bug %d: %v", clone.ID, err)
+		}
+	}
+	return nil
+}
+
+// robotCommentExists reports whether bugID already carries a comment with the exact text of
+// message, posted by the verification bot, so callers can avoid reposting a duplicate.
+func (c *Verifier) robotCommentExists(bugID int, message string) (bool, error) {
+	comments, err := c.bzClient.GetComments(bugID)
+	if err != nil {
+		return false, fmt.Errorf("Failed to get comments on bug %d: %v", bugID, err)
+	}
+	for _, comment := range comments {
+		if comment.Text == message && (comment.Creator == "openshift-bugzilla-robot" || comment.Creator == "openshift-bugzilla-robot@redhat.com") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetPRs identifies bugzilla bugs and the associated github PRs fixed in a release from a
+// given buglist generated by `oc adm release info --bugs=git-cache-path --ouptut=name from-tag
+// to-tag`. For every bug with at least one linked PR it also fetches the bug itself and the ID
+// of the root of its clone chain (see GetRoot), so that callers such as VerifyBugs and the
+// release notes composer don't each have to re-fetch the bug.
+func (c *Verifier) GetPRs(input []int) (map[int]*BugPRs, []error) {
+	bzPRs := make(map[int]*BugPRs)
 	var errs []error
 	for _, bzID := range input {
-		extBugs, err := bzClient.GetExternalBugPRsOnBug(bzID)
+		extBugs, err := c.bzClient.GetExternalBugPRsOnBug(bzID)
 		if err != nil {
 			// there are a couple of bugs with weird permissions issues that can cause this to fail; simply log instead of generating error
 			if bugzilla.IsAccessDenied(err) {
@@ -171,21 +411,28 @@ func getPRs(input []int, bzClient bugzilla.Client) (map[int][]pr, []error) {
 			}
 			continue
 		}
-		foundPR := false
+		var prs []PR
 		for _, extBug := range extBugs {
 			if extBug.Type.URL == "https://github.com/" {
-				if existingPRs, ok := bzPRs[bzID]; ok {
-					bzPRs[bzID] = append(existingPRs, pr{org: extBug.Org, repo: extBug.Repo, prNum: extBug.Num})
-				} else {
-					bzPRs[bzID] = []pr{{org: extBug.Org, repo: extBug.Repo, prNum: extBug.Num}}
-				}
-				foundPR = true
+				prs = append(prs, PR{Org: extBug.Org, Repo: extBug.Repo, Num: extBug.Num})
 			}
 		}
-		if !foundPR {
+		if len(prs) == 0 {
 			// sometimes people ignore the bot and manually change the bugzilla tags, resulting in a bug not being linked; ignore these
 			klog.V(5).Infof("Failed to identify associated GitHub PR for bugzilla bug %d", bzID)
+			continue
+		}
+		bug, err := c.bzClient.GetBug(bzID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to get bugzilla number %d: %v", bzID, err))
+			continue
+		}
+		root, err := c.GetRoot(bug)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to determine root bug for bugzilla bug %d: %v", bzID, err))
+			continue
 		}
+		bzPRs[bzID] = &BugPRs{Bug: bug, PRs: prs, RootID: root.ID}
 	}
 	return bzPRs, errs
 }
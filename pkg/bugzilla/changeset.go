@@ -0,0 +1,132 @@
+package bugzilla
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/test-infra/prow/bugzilla"
+	"k8s.io/test-infra/prow/github"
+)
+
+// ChangesetPolicy configures quorum-based approval across a bug's full set of linked PRs (its
+// "changeset"), instead of requiring every individual PR to carry its own QA approval. This
+// avoids forcing redundant re-reviews from the same QA contact when a single bug fix spans
+// multiple repos/PRs.
+type ChangesetPolicy struct {
+	// MinApprovers is the minimum number of distinct GitHub users who must have an approving
+	// review somewhere in the changeset. Defaults to 1.
+	MinApprovers int `json:"min_approvers,omitempty"`
+	// QAApprovalSatisfiesSet, if true, means an approving review from the bug's QA contact on
+	// any single PR in the changeset satisfies the quorum for the whole changeset, regardless
+	// of MinApprovers.
+	QAApprovalSatisfiesSet bool `json:"qa_approval_satisfies_set,omitempty"`
+	// ExcludedPathPrefixes lists file path prefixes (e.g. "docs/"). A PR whose changed files
+	// all fall under one of these prefixes is excluded from the changeset entirely.
+	ExcludedPathPrefixes []string `json:"excluded_path_prefixes,omitempty"`
+}
+
+// evaluateChangeset determines whether bug's changeset meets policy's approval quorum. It
+// fetches each non-excluded PR's reviews via ghClient.ListReviews and computes the union of
+// approvers across the whole set. forbiddenLabels is the RepoPolicy-level ForbiddenLabels list;
+// it applies here the same way it does to the per-PR path, since Changeset only replaces the
+// RequiredLabels/RequireMerged checks, not the forbidden-label guard — a single PR carrying one
+// of these labels (e.g. `do-not-merge/hold`) fails the whole changeset regardless of quorum.
+func (c *Verifier) evaluateChangeset(bug *bugzilla.Bug, extPRs []PR, policy ChangesetPolicy, forbiddenLabels []string) (bool, []error) {
+	var errs []error
+	approvers := make(map[string]bool)
+	var qaApproved bool
+	var consideredPRs int
+	var forbidden bool
+	qaLogin, hasQALogin := c.qaContactGitHubLogin(bug)
+	for _, extPR := range extPRs {
+		excluded, err := c.prIsExcluded(extPR, policy.ExcludedPathPrefixes)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if excluded {
+			continue
+		}
+		consideredPRs++
+		if len(forbiddenLabels) > 0 {
+			labels, err := c.ghClient.GetIssueLabels(extPR.Org, extPR.Repo, extPR.Num)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("Unable to get labels for github pull %s/%s#%d: %v", extPR.Org, extPR.Repo, extPR.Num, err))
+				continue
+			}
+			if bad := forbiddenLabelOf(labels, forbiddenLabels); bad != "" {
+				forbidden = true
+				errs = append(errs, fmt.Errorf("PR %s/%s#%d carries forbidden label %q", extPR.Org, extPR.Repo, extPR.Num, bad))
+				continue
+			}
+		}
+		reviews, err := c.ghClient.ListReviews(extPR.Org, extPR.Repo, extPR.Num)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to get reviews for github pull %s/%s#%d: %v", extPR.Org, extPR.Repo, extPR.Num, err))
+			continue
+		}
+		for _, review := range reviews {
+			if review.State != github.ReviewStateApproved {
+				continue
+			}
+			approvers[review.User.Login] = true
+			if hasQALogin && review.User.Login == qaLogin {
+				qaApproved = true
+			}
+		}
+	}
+	if consideredPRs == 0 {
+		return false, append(errs, fmt.Errorf("changeset has no PRs left to verify after excluding non-code PRs"))
+	}
+	if forbidden {
+		return false, errs
+	}
+	minApprovers := policy.MinApprovers
+	if minApprovers <= 0 {
+		minApprovers = 1
+	}
+	verified := len(approvers) >= minApprovers || (policy.QAApprovalSatisfiesSet && qaApproved)
+	return verified, errs
+}
+
+// forbiddenLabelOf returns the first label in forbiddenLabels that appears in labels, or "" if
+// none do.
+func forbiddenLabelOf(labels []github.Label, forbiddenLabels []string) string {
+	labelSet := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		labelSet[label.Name] = true
+	}
+	for _, bad := range forbiddenLabels {
+		if labelSet[bad] {
+			return bad
+		}
+	}
+	return ""
+}
+
+// prIsExcluded reports whether every file changed by extPR falls under one of excludedPrefixes,
+// fetching the PR's changed files via ghClient.GetPullRequestChanges.
+func (c *Verifier) prIsExcluded(extPR PR, excludedPrefixes []string) (bool, error) {
+	if len(excludedPrefixes) == 0 {
+		return false, nil
+	}
+	changes, err := c.ghClient.GetPullRequestChanges(extPR.Org, extPR.Repo, extPR.Num)
+	if err != nil {
+		return false, fmt.Errorf("Unable to get changed files for github pull %s/%s#%d: %v", extPR.Org, extPR.Repo, extPR.Num, err)
+	}
+	for _, change := range changes {
+		if !hasAnyPrefix(change.Filename, excludedPrefixes) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
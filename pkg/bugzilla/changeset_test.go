@@ -0,0 +1,127 @@
+package bugzilla
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/test-infra/prow/bugzilla"
+	"k8s.io/test-infra/prow/github"
+)
+
+// stubChangesetClient is a github.Client that only serves ListReviews, GetIssueLabels and
+// GetPullRequestChanges from in-memory fixtures, embedding the interface so the remaining
+// methods are never called by the code under test.
+type stubChangesetClient struct {
+	github.Client
+	reviews map[string][]github.Review
+	labels  map[string][]github.Label
+}
+
+func (s *stubChangesetClient) ListReviews(org, repo string, number int) ([]github.Review, error) {
+	return s.reviews[prKey(org, repo, number)], nil
+}
+
+func (s *stubChangesetClient) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
+	return nil, nil
+}
+
+func (s *stubChangesetClient) GetIssueLabels(org, repo string, number int) ([]github.Label, error) {
+	return s.labels[prKey(org, repo, number)], nil
+}
+
+func prKey(org, repo string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", org, repo, number)
+}
+
+func approvedReview(login string) github.Review {
+	return github.Review{User: github.User{Login: login}, State: github.ReviewStateApproved}
+}
+
+func TestEvaluateChangesetQAApprovalSatisfiesSet(t *testing.T) {
+	bug := &bugzilla.Bug{ID: 1, QAContactDetail: &bugzilla.User{Name: "Jane QA", Email: "jane@example.com"}}
+	pr := PR{Org: "openshift", Repo: "origin", Num: 1}
+	c := &Verifier{
+		ghClient: &stubChangesetClient{reviews: map[string][]github.Review{
+			prKey(pr.Org, pr.Repo, pr.Num): {approvedReview("jane-gh")},
+		}},
+		qaContactGitHubLogins: map[string]string{"jane@example.com": "jane-gh"},
+	}
+	policy := ChangesetPolicy{MinApprovers: 2, QAApprovalSatisfiesSet: true}
+
+	verified, errs := c.evaluateChangeset(bug, []PR{pr}, policy, nil)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if !verified {
+		t.Fatal("evaluateChangeset() = false, want true: a single QA-contact approval should satisfy QAApprovalSatisfiesSet regardless of MinApprovers")
+	}
+}
+
+func TestEvaluateChangesetQAApprovalRequiresMapping(t *testing.T) {
+	// Without a configured GitHub-login mapping for the QA contact, an approval from someone
+	// who happens to share the QA contact's bugzilla display name must NOT count as QA
+	// approval: a review is only ever identified by GitHub login.
+	bug := &bugzilla.Bug{ID: 1, QAContactDetail: &bugzilla.User{Name: "Jane QA", Email: "jane@example.com"}}
+	pr := PR{Org: "openshift", Repo: "origin", Num: 1}
+	c := &Verifier{
+		ghClient: &stubChangesetClient{reviews: map[string][]github.Review{
+			prKey(pr.Org, pr.Repo, pr.Num): {approvedReview("Jane QA")},
+		}},
+	}
+	policy := ChangesetPolicy{MinApprovers: 2, QAApprovalSatisfiesSet: true}
+
+	verified, errs := c.evaluateChangeset(bug, []PR{pr}, policy, nil)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if verified {
+		t.Fatal("evaluateChangeset() = true, want false: single approval should not satisfy MinApprovers=2 without a real QA-login match")
+	}
+}
+
+func TestEvaluateChangesetMinApproversFallback(t *testing.T) {
+	bug := &bugzilla.Bug{ID: 1}
+	pr := PR{Org: "openshift", Repo: "origin", Num: 1}
+	c := &Verifier{
+		ghClient: &stubChangesetClient{reviews: map[string][]github.Review{
+			prKey(pr.Org, pr.Repo, pr.Num): {approvedReview("alice"), approvedReview("bob")},
+		}},
+	}
+	policy := ChangesetPolicy{MinApprovers: 2}
+
+	verified, errs := c.evaluateChangeset(bug, []PR{pr}, policy, nil)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if !verified {
+		t.Fatal("evaluateChangeset() = false, want true: two distinct approvers should satisfy MinApprovers=2")
+	}
+}
+
+func TestEvaluateChangesetForbiddenLabelBlocksQuorum(t *testing.T) {
+	// One PR in the changeset carries a forbidden hold label; a second PR has plenty of
+	// approvals. The forbidden label must fail the whole changeset even though quorum is met
+	// elsewhere.
+	bug := &bugzilla.Bug{ID: 1}
+	held := PR{Org: "openshift", Repo: "origin", Num: 1}
+	clean := PR{Org: "openshift", Repo: "origin", Num: 2}
+	c := &Verifier{
+		ghClient: &stubChangesetClient{
+			reviews: map[string][]github.Review{
+				prKey(clean.Org, clean.Repo, clean.Num): {approvedReview("alice"), approvedReview("bob")},
+			},
+			labels: map[string][]github.Label{
+				prKey(held.Org, held.Repo, held.Num): {{Name: "do-not-merge/hold"}},
+			},
+		},
+	}
+	policy := ChangesetPolicy{MinApprovers: 1}
+
+	verified, errs := c.evaluateChangeset(bug, []PR{held, clean}, policy, []string{"do-not-merge/hold"})
+	if verified {
+		t.Fatal("evaluateChangeset() = true, want false: a forbidden label on any PR in the changeset must block verification")
+	}
+	if len(errs) == 0 {
+		t.Fatal("evaluateChangeset() returned no errors explaining the forbidden label")
+	}
+}
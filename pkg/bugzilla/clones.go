@@ -0,0 +1,68 @@
+package bugzilla
+
+import (
+	"fmt"
+
+	"k8s.io/test-infra/prow/bugzilla"
+)
+
+// GetAllClones walks bug's Blocks and DependsOn links in both directions and
+// returns every bug reachable from it that shares the same Summary as bug,
+// deduplicated by ID. bug itself is never included in the result.
+func (c *Verifier) GetAllClones(bug *bugzilla.Bug) ([]*bugzilla.Bug, error) {
+	seen := map[int]bool{bug.ID: true}
+	var clones []*bugzilla.Bug
+	queue := append(append([]int{}, bug.Blocks...), bug.DependsOn...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		candidate, err := c.bzClient.GetBug(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bugzilla bug %d: %w", id, err)
+		}
+		if candidate.Summary != bug.Summary {
+			continue
+		}
+		clones = append(clones, candidate)
+		queue = append(queue, candidate.Blocks...)
+		queue = append(queue, candidate.DependsOn...)
+	}
+	return clones, nil
+}
+
+// GetRoot climbs bug's DependsOn chain for as long as the parent's Summary
+// matches bug's Summary and returns the topmost such ancestor. If bug has no
+// matching parent, bug itself is returned as the root. Bugzilla DependsOn
+// links are hand-maintained and occasionally cyclic, so a seen set guards
+// against looping forever on a bug that (mistakenly) depends on one of its
+// own ancestors; already-visited parents are skipped, and the climb returns
+// the current bug once it has no unvisited parent with a matching Summary.
+func (c *Verifier) GetRoot(bug *bugzilla.Bug) (*bugzilla.Bug, error) {
+	seen := map[int]bool{bug.ID: true}
+	current := bug
+	for {
+		var parent *bugzilla.Bug
+		for _, depID := range current.DependsOn {
+			if seen[depID] {
+				continue
+			}
+			candidate, err := c.bzClient.GetBug(depID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get bugzilla bug %d: %w", depID, err)
+			}
+			if candidate.Summary == bug.Summary {
+				parent = candidate
+				break
+			}
+		}
+		if parent == nil {
+			return current, nil
+		}
+		seen[parent.ID] = true
+		current = parent
+	}
+}
@@ -0,0 +1,115 @@
+package bugzilla
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/test-infra/prow/bugzilla"
+)
+
+// stubCloneClient is a bugzilla.Client that only serves GetBug from an in-memory bug set,
+// embedding the interface so the remaining methods are never called by the code under test.
+type stubCloneClient struct {
+	bugzilla.Client
+	bugs map[int]*bugzilla.Bug
+}
+
+func (s *stubCloneClient) GetBug(id int) (*bugzilla.Bug, error) {
+	bug, ok := s.bugs[id]
+	if !ok {
+		return nil, fmt.Errorf("no such bug %d", id)
+	}
+	return bug, nil
+}
+
+func TestGetRootCyclicDependsOn(t *testing.T) {
+	// bug 1 and bug 2 mistakenly depend on each other; both share a summary, so naive climbing
+	// would bounce between them forever.
+	bugs := map[int]*bugzilla.Bug{
+		1: {ID: 1, Summary: "widget broken", DependsOn: []int{2}},
+		2: {ID: 2, Summary: "widget broken", DependsOn: []int{1}},
+	}
+	c := &Verifier{bzClient: &stubCloneClient{bugs: bugs}}
+
+	done := make(chan struct{})
+	var root *bugzilla.Bug
+	var err error
+	go func() {
+		root, err = c.GetRoot(bugs[1])
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetRoot did not terminate on a cyclic DependsOn chain")
+	}
+	if err != nil {
+		t.Fatalf("GetRoot returned error: %v", err)
+	}
+	if root == nil {
+		t.Fatal("GetRoot returned a nil root")
+	}
+}
+
+func TestGetRootChain(t *testing.T) {
+	bugs := map[int]*bugzilla.Bug{
+		1: {ID: 1, Summary: "widget broken", DependsOn: []int{2}},
+		2: {ID: 2, Summary: "widget broken", DependsOn: []int{3}},
+		3: {ID: 3, Summary: "widget broken"},
+	}
+	c := &Verifier{bzClient: &stubCloneClient{bugs: bugs}}
+
+	root, err := c.GetRoot(bugs[1])
+	if err != nil {
+		t.Fatalf("GetRoot returned error: %v", err)
+	}
+	if root.ID != 3 {
+		t.Fatalf("GetRoot returned bug %d, want 3", root.ID)
+	}
+}
+
+func TestGetRootNoMatchingParent(t *testing.T) {
+	bugs := map[int]*bugzilla.Bug{
+		1: {ID: 1, Summary: "widget broken", DependsOn: []int{2}},
+		2: {ID: 2, Summary: "unrelated bug"},
+	}
+	c := &Verifier{bzClient: &stubCloneClient{bugs: bugs}}
+
+	root, err := c.GetRoot(bugs[1])
+	if err != nil {
+		t.Fatalf("GetRoot returned error: %v", err)
+	}
+	if root.ID != 1 {
+		t.Fatalf("GetRoot returned bug %d, want 1 (bug itself)", root.ID)
+	}
+}
+
+func TestGetAllClonesDedupDiamond(t *testing.T) {
+	// 1 blocks 2 and 3, both of which block 4; a naive traversal without dedup would visit
+	// bug 4 twice.
+	bugs := map[int]*bugzilla.Bug{
+		1: {ID: 1, Summary: "widget broken", Blocks: []int{2, 3}},
+		2: {ID: 2, Summary: "widget broken", Blocks: []int{4}},
+		3: {ID: 3, Summary: "widget broken", Blocks: []int{4}},
+		4: {ID: 4, Summary: "widget broken"},
+	}
+	c := &Verifier{bzClient: &stubCloneClient{bugs: bugs}}
+
+	clones, err := c.GetAllClones(bugs[1])
+	if err != nil {
+		t.Fatalf("GetAllClones returned error: %v", err)
+	}
+	seen := map[int]int{}
+	for _, clone := range clones {
+		seen[clone.ID]++
+	}
+	for id, count := range seen {
+		if count > 1 {
+			t.Errorf("bug %d was returned %d times, want at most once", id, count)
+		}
+	}
+	if len(clones) != 3 {
+		t.Fatalf("GetAllClones returned %d clones, want 3", len(clones))
+	}
+}
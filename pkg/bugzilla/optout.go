@@ -0,0 +1,170 @@
+package bugzilla
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/test-infra/prow/bugzilla"
+)
+
+// optOutMarker and optInMarker are the comment markers a QA contact posts on a bug (or on a
+// dedicated tracker bug) to opt out of, or back into, being pinged by verification comments.
+const (
+	optOutMarker = "#verify-optout"
+	optInMarker  = "#verify-optin"
+)
+
+// QAOptOutStore tracks which bugzilla QA contacts (identified by email) have opted out of being
+// pinged by automated verification comments. Bugzilla QA contacts are often stale, so without
+// this a release gets a ping on every one of their bugs regardless of whether they ever act on it.
+type QAOptOutStore interface {
+	// IsOptedOut reports whether email has opted out of verification pings.
+	IsOptedOut(email string) (bool, error)
+	// SetOptedOut records email's opt-out state.
+	SetOptedOut(email string, optedOut bool) error
+}
+
+// ProcessOptOutComment inspects a single bugzilla comment and, if it contains the
+// #verify-optout or #verify-optin marker, updates store with the comment author's opt-out
+// state. It is meant to be called for every new comment observed on a bug — typically a QA
+// contact's own bugs, or a dedicated opt-out tracker bug — by whatever polls bugzilla for
+// new comments. comment.Creator is keyed the same way as bugzilla.Bug.QAContactDetail.Email,
+// so a QA contact's own opt-out comment is recognized by resolveQAContact without any extra
+// lookup.
+func ProcessOptOutComment(store QAOptOutStore, comment bugzilla.Comment) error {
+	text := strings.ToLower(comment.Text)
+	switch {
+	case strings.Contains(text, optOutMarker):
+		return store.SetOptedOut(comment.Creator, true)
+	case strings.Contains(text, optInMarker):
+		return store.SetOptedOut(comment.Creator, false)
+	default:
+		return nil
+	}
+}
+
+// FileQAOptOutStore is a QAOptOutStore backed by a JSON file on disk, keyed by lower-cased
+// bugzilla user email.
+type FileQAOptOutStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileQAOptOutStore returns a FileQAOptOutStore backed by the file at path. The file is
+// created on first write if it does not already exist.
+func NewFileQAOptOutStore(path string) *FileQAOptOutStore {
+	return &FileQAOptOutStore{path: path}
+}
+
+func (s *FileQAOptOutStore) IsOptedOut(email string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	optOuts, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	return optOuts[strings.ToLower(email)], nil
+}
+
+func (s *FileQAOptOutStore) SetOptedOut(email string, optedOut bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	optOuts, err := s.read()
+	if err != nil {
+		return err
+	}
+	key := strings.ToLower(email)
+	if optedOut {
+		optOuts[key] = true
+	} else {
+		delete(optOuts, key)
+	}
+	return s.write(optOuts)
+}
+
+func (s *FileQAOptOutStore) read() (map[string]bool, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read QA opt-out store %s: %w", s.path, err)
+	}
+	optOuts := map[string]bool{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &optOuts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal QA opt-out store %s: %w", s.path, err)
+		}
+	}
+	return optOuts, nil
+}
+
+func (s *FileQAOptOutStore) write(optOuts map[string]bool) error {
+	data, err := json.MarshalIndent(optOuts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal QA opt-out store: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write QA opt-out store %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// configMapKey derives a ConfigMap data key for email. ConfigMap data keys must match
+// `[-._a-zA-Z0-9]+`, which an email address's `@` violates, so the lower-cased email is
+// hex-encoded as a SHA-256 digest instead of being used as a key directly.
+func configMapKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ConfigMapQAOptOutStore is a QAOptOutStore backed by a Kubernetes ConfigMap, with one data key
+// per opted-out bugzilla user email set to "true". The key is derived from the email via
+// configMapKey, since a raw email address is not a valid ConfigMap data key.
+type ConfigMapQAOptOutStore struct {
+	client corev1client.ConfigMapInterface
+	name   string
+}
+
+// NewConfigMapQAOptOutStore returns a ConfigMapQAOptOutStore backed by the named ConfigMap,
+// accessed via client.
+func NewConfigMapQAOptOutStore(client corev1client.ConfigMapInterface, name string) *ConfigMapQAOptOutStore {
+	return &ConfigMapQAOptOutStore{client: client, name: name}
+}
+
+func (s *ConfigMapQAOptOutStore) IsOptedOut(email string) (bool, error) {
+	cm, err := s.client.Get(context.TODO(), s.name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get QA opt-out configmap %s: %w", s.name, err)
+	}
+	return cm.Data[configMapKey(email)] == "true", nil
+}
+
+func (s *ConfigMapQAOptOutStore) SetOptedOut(email string, optedOut bool) error {
+	cm, err := s.client.Get(context.TODO(), s.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get QA opt-out configmap %s: %w", s.name, err)
+	}
+	updated := cm.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = map[string]string{}
+	}
+	key := configMapKey(email)
+	if optedOut {
+		updated.Data[key] = "true"
+	} else {
+		delete(updated.Data, key)
+	}
+	if _, err := s.client.Update(context.TODO(), updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update QA opt-out configmap %s: %w", s.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package bugzilla
+
+import (
+	"regexp"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var validConfigMapKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+func TestConfigMapKeyIsValid(t *testing.T) {
+	for _, email := range []string{"jane@example.com", "Jane.Doe+qa@example.com", "bob@sub.example.co"} {
+		key := configMapKey(email)
+		if !validConfigMapKey.MatchString(key) {
+			t.Errorf("configMapKey(%q) = %q, not a valid ConfigMap data key", email, key)
+		}
+	}
+}
+
+func TestConfigMapQAOptOutStoreSetAndIsOptedOut(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "qa-opt-outs", Namespace: "default"},
+	})
+	store := NewConfigMapQAOptOutStore(client.CoreV1().ConfigMaps("default"), "qa-opt-outs")
+
+	email := "jane@example.com"
+	optedOut, err := store.IsOptedOut(email)
+	if err != nil {
+		t.Fatalf("IsOptedOut returned error: %v", err)
+	}
+	if optedOut {
+		t.Fatal("IsOptedOut() = true before SetOptedOut was ever called")
+	}
+
+	if err := store.SetOptedOut(email, true); err != nil {
+		t.Fatalf("SetOptedOut returned error: %v", err)
+	}
+	optedOut, err = store.IsOptedOut(email)
+	if err != nil {
+		t.Fatalf("IsOptedOut returned error: %v", err)
+	}
+	if !optedOut {
+		t.Fatal("IsOptedOut() = false after SetOptedOut(true)")
+	}
+
+	if err := store.SetOptedOut(email, false); err != nil {
+		t.Fatalf("SetOptedOut returned error: %v", err)
+	}
+	optedOut, err = store.IsOptedOut(email)
+	if err != nil {
+		t.Fatalf("IsOptedOut returned error: %v", err)
+	}
+	if optedOut {
+		t.Fatal("IsOptedOut() = true after SetOptedOut(false)")
+	}
+}
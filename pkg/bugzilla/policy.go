@@ -0,0 +1,162 @@
+package bugzilla
+
+import (
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Default values applied to any RepoPolicy field left unset, mirroring the
+// behavior VerifyBugs had before VerifierPolicy was introduced.
+const (
+	defaultIncomingStatus = "ON_QA"
+	defaultTargetStatus   = "VERIFIED"
+)
+
+var defaultRequiredLabels = []string{"qe-approved"}
+
+// RepoPolicy describes the verification requirements for bugs whose linked PRs
+// land in a particular org/repo, optionally scoped further to a single branch.
+// A branch-specific entry overrides the repo-wide entry for the same org/repo,
+// field by field, analogous to the prow bugzilla plugin's per-branch options.
+type RepoPolicy struct {
+	Org    string `json:"org"`
+	Repo   string `json:"repo"`
+	Branch string `json:"branch,omitempty"`
+
+	// IncomingStatus is the bug status required before verification is attempted.
+	// Defaults to ON_QA.
+	IncomingStatus string `json:"incoming_status,omitempty"`
+	// TargetStatus is the status a bug is moved to once verification succeeds.
+	// Defaults to VERIFIED.
+	TargetStatus string `json:"target_status,omitempty"`
+	// RequiredLabels lists GitHub labels every linked PR must carry. Defaults to {qe-approved}.
+	RequiredLabels []string `json:"required_labels,omitempty"`
+	// ForbiddenLabels lists GitHub labels that block verification if present on any linked PR,
+	// e.g. `do-not-merge/hold`.
+	ForbiddenLabels []string `json:"forbidden_labels,omitempty"`
+	// RequireMerged, if true, requires every linked PR to be merged, not just labeled,
+	// before the bug is verified.
+	RequireMerged bool `json:"require_merged,omitempty"`
+	// TargetReleasePattern, if set, is a regex the bug's TargetRelease must match. A bug whose
+	// TargetRelease does not match is skipped and not verified.
+	TargetReleasePattern string `json:"target_release_pattern,omitempty"`
+	// Changeset, if set, switches verification from the per-PR RequiredLabels/RequireMerged
+	// checks to quorum-based review approval across the bug's full set of linked PRs.
+	// ForbiddenLabels still applies to every PR in the changeset.
+	Changeset *ChangesetPolicy `json:"changeset,omitempty"`
+
+	targetReleaseRegexp *regexp.Regexp
+}
+
+// VerifierPolicy is the set of RepoPolicy entries a Verifier evaluates bugs against, along
+// with a global dry-run toggle.
+type VerifierPolicy struct {
+	Repos []RepoPolicy `json:"repos,omitempty"`
+	// DryRun, if true, makes VerifyBugs only post the explanatory comment on a bug and never
+	// call UpdateBug, so a new policy can be rolled out safely before it takes effect.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// LoadVerifierPolicy parses a VerifierPolicy from YAML.
+func LoadVerifierPolicy(data []byte) (*VerifierPolicy, error) {
+	var policy VerifierPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal verifier policy: %w", err)
+	}
+	for i := range policy.Repos {
+		if err := policy.Repos[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+	return &policy, nil
+}
+
+func (p *RepoPolicy) compile() error {
+	if p.TargetReleasePattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(p.TargetReleasePattern)
+	if err != nil {
+		return fmt.Errorf("invalid target_release_pattern %q for %s/%s: %w", p.TargetReleasePattern, p.Org, p.Repo, err)
+	}
+	p.targetReleaseRegexp = re
+	return nil
+}
+
+// hasBranchPolicy reports whether policy has a branch-specific entry for org/repo, so callers
+// can skip the extra GitHub lookup needed to determine a PR's branch when it isn't needed.
+func (policy *VerifierPolicy) hasBranchPolicy(org, repo string) bool {
+	for _, p := range policy.Repos {
+		if p.Org == org && p.Repo == repo && p.Branch != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the effective policy for a PR landing in org/repo on branch, applying
+// defaults for any field left unset by the matching entries. A branch-specific entry
+// overrides a repo-wide entry for the same org/repo, field by field.
+func (policy *VerifierPolicy) resolve(org, repo, branch string) RepoPolicy {
+	var repoWide, branchSpecific *RepoPolicy
+	for i := range policy.Repos {
+		p := &policy.Repos[i]
+		if p.Org != org || p.Repo != repo {
+			continue
+		}
+		if p.Branch == "" {
+			repoWide = p
+		} else if p.Branch == branch {
+			branchSpecific = p
+		}
+	}
+	resolved := RepoPolicy{Org: org, Repo: repo, Branch: branch}
+	for _, p := range []*RepoPolicy{repoWide, branchSpecific} {
+		if p == nil {
+			continue
+		}
+		if p.IncomingStatus != "" {
+			resolved.IncomingStatus = p.IncomingStatus
+		}
+		if p.TargetStatus != "" {
+			resolved.TargetStatus = p.TargetStatus
+		}
+		if len(p.RequiredLabels) > 0 {
+			resolved.RequiredLabels = p.RequiredLabels
+		}
+		if len(p.ForbiddenLabels) > 0 {
+			resolved.ForbiddenLabels = p.ForbiddenLabels
+		}
+		if p.RequireMerged {
+			resolved.RequireMerged = true
+		}
+		if p.targetReleaseRegexp != nil {
+			resolved.targetReleaseRegexp = p.targetReleaseRegexp
+		}
+		if p.Changeset != nil {
+			resolved.Changeset = p.Changeset
+		}
+	}
+	if resolved.IncomingStatus == "" {
+		resolved.IncomingStatus = defaultIncomingStatus
+	}
+	if resolved.TargetStatus == "" {
+		resolved.TargetStatus = defaultTargetStatus
+	}
+	if len(resolved.RequiredLabels) == 0 {
+		resolved.RequiredLabels = defaultRequiredLabels
+	}
+	return resolved
+}
+
+// defaultRepoPolicy is the policy applied when a Verifier has no VerifierPolicy configured,
+// preserving the hardcoded ON_QA/VERIFIED/qe-approved behavior.
+func defaultRepoPolicy() RepoPolicy {
+	return RepoPolicy{
+		IncomingStatus: defaultIncomingStatus,
+		TargetStatus:   defaultTargetStatus,
+		RequiredLabels: defaultRequiredLabels,
+	}
+}
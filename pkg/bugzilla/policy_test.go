@@ -0,0 +1,82 @@
+package bugzilla
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVerifierPolicyResolve(t *testing.T) {
+	policy := &VerifierPolicy{
+		Repos: []RepoPolicy{
+			{
+				Org:            "openshift",
+				Repo:           "origin",
+				IncomingStatus: "ON_QA",
+				RequiredLabels: []string{"qe-approved"},
+			},
+			{
+				Org:            "openshift",
+				Repo:           "origin",
+				Branch:         "release-4.16",
+				TargetStatus:   "VERIFIED_CUSTOM",
+				RequireMerged:  true,
+				RequiredLabels: []string{"backport-approved"},
+			},
+		},
+	}
+
+	t.Run("repo-wide only", func(t *testing.T) {
+		resolved := policy.resolve("openshift", "origin", "master")
+		want := RepoPolicy{
+			Org:            "openshift",
+			Repo:           "origin",
+			Branch:         "master",
+			IncomingStatus: "ON_QA",
+			TargetStatus:   defaultTargetStatus,
+			RequiredLabels: []string{"qe-approved"},
+		}
+		if !reflect.DeepEqual(resolved, want) {
+			t.Fatalf("resolve() = %+v, want %+v", resolved, want)
+		}
+	})
+
+	t.Run("branch-specific overrides field by field", func(t *testing.T) {
+		resolved := policy.resolve("openshift", "origin", "release-4.16")
+		if resolved.IncomingStatus != "ON_QA" {
+			t.Errorf("IncomingStatus = %q, want inherited %q", resolved.IncomingStatus, "ON_QA")
+		}
+		if resolved.TargetStatus != "VERIFIED_CUSTOM" {
+			t.Errorf("TargetStatus = %q, want branch override %q", resolved.TargetStatus, "VERIFIED_CUSTOM")
+		}
+		if !resolved.RequireMerged {
+			t.Errorf("RequireMerged = false, want true from branch override")
+		}
+		if !reflect.DeepEqual(resolved.RequiredLabels, []string{"backport-approved"}) {
+			t.Errorf("RequiredLabels = %v, want branch override", resolved.RequiredLabels)
+		}
+	})
+
+	t.Run("no matching policy applies defaults", func(t *testing.T) {
+		resolved := policy.resolve("other", "repo", "")
+		want := defaultRepoPolicy()
+		want.Org, want.Repo = "other", "repo"
+		if !reflect.DeepEqual(resolved, want) {
+			t.Fatalf("resolve() = %+v, want %+v", resolved, want)
+		}
+	})
+}
+
+func TestHasBranchPolicy(t *testing.T) {
+	policy := &VerifierPolicy{
+		Repos: []RepoPolicy{
+			{Org: "openshift", Repo: "origin"},
+			{Org: "openshift", Repo: "installer", Branch: "release-4.16"},
+		},
+	}
+	if policy.hasBranchPolicy("openshift", "origin") {
+		t.Error("hasBranchPolicy(origin) = true, want false (repo-wide entry only)")
+	}
+	if !policy.hasBranchPolicy("openshift", "installer") {
+		t.Error("hasBranchPolicy(installer) = false, want true (has branch-specific entry)")
+	}
+}
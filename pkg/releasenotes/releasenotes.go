@@ -0,0 +1,263 @@
+// Package releasenotes builds a release-notes document from the bugzilla bugs fixed in a
+// release, grouping entries by bugzilla component and sub-component.
+package releasenotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	bzverifier "github.com/openshift/release-controller/pkg/bugzilla"
+	"k8s.io/test-infra/prow/bugzilla"
+	"k8s.io/test-infra/prow/github"
+)
+
+// Category classifies a release note entry using a conventional-commit-style prefix mined from
+// the titles of its linked PRs.
+type Category string
+
+const (
+	CategoryBreaking Category = "breaking"
+	CategoryFeature  Category = "feature"
+	CategoryFix      Category = "fix"
+	CategoryRevert   Category = "revert"
+	CategoryOther    Category = "other"
+)
+
+// categoryPrefixes is checked in order, so CategoryBreaking takes precedence over the others
+// when a PR title carries more than one marker.
+var categoryPrefixes = []struct {
+	prefix   string
+	category Category
+}{
+	{":warning:", CategoryBreaking},
+	{"revert:", CategoryRevert},
+	{"feat:", CategoryFeature},
+	{"fix:", CategoryFix},
+}
+
+// categorize returns the Category of the first marker in categoryPrefixes that appears anywhere
+// in title (case-insensitively), or CategoryOther if none match.
+func categorize(title string) Category {
+	lower := strings.ToLower(strings.TrimSpace(title))
+	for _, cp := range categoryPrefixes {
+		if strings.Contains(lower, cp.prefix) {
+			return cp.category
+		}
+	}
+	return CategoryOther
+}
+
+// PRRef is a link to a single GitHub PR contributing to an Entry.
+type PRRef struct {
+	Org   string `json:"org"`
+	Repo  string `json:"repo"`
+	Num   int    `json:"num"`
+	Title string `json:"title"`
+}
+
+// Entry is a single release note, collapsing every PR linked to one bugzilla bug.
+type Entry struct {
+	BugID         int      `json:"bugID"`
+	RootID        int      `json:"rootID"`
+	Summary       string   `json:"summary"`
+	Severity      string   `json:"severity"`
+	Component     string   `json:"component"`
+	SubComponents []string `json:"subComponents,omitempty"`
+	TargetRelease string   `json:"targetRelease"`
+	Category      Category `json:"category"`
+	PRs           []PRRef  `json:"prs"`
+}
+
+// SubComponentNotes groups every Entry for a single sub-component. SubComponent is empty for
+// bugs that have none.
+type SubComponentNotes struct {
+	SubComponent string  `json:"subComponent,omitempty"`
+	Entries      []Entry `json:"entries"`
+}
+
+// ComponentNotes groups every Entry for a single bugzilla component, broken down further by
+// sub-component.
+type ComponentNotes struct {
+	Component     string              `json:"component"`
+	SubComponents []SubComponentNotes `json:"subComponents"`
+}
+
+// Notes is a release-notes document for a single accepted tag, grouped by component and
+// sub-component.
+type Notes struct {
+	TagName    string           `json:"tagName"`
+	Components []ComponentNotes `json:"components"`
+}
+
+// Markdown renders notes as a Markdown document grouped by component and sub-component.
+func (n *Notes) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Release notes for %s\n\n", n.TagName)
+	for _, component := range n.Components {
+		fmt.Fprintf(&b, "## %s\n\n", component.Component)
+		for _, sub := range component.SubComponents {
+			if sub.SubComponent != "" {
+				fmt.Fprintf(&b, "### %s\n\n", sub.SubComponent)
+			}
+			for _, entry := range sub.Entries {
+				b.WriteString(formatEntry(entry))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func formatEntry(entry Entry) string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if label := categoryLabel(entry.Category); label != "" {
+		fmt.Fprintf(&b, "%s ", label)
+	}
+	b.WriteString(entry.Summary)
+	if entry.RootID != 0 && entry.RootID != entry.BugID {
+		fmt.Fprintf(&b, " (Bugzilla bug %d, clone of %d)", entry.BugID, entry.RootID)
+	} else {
+		fmt.Fprintf(&b, " (Bugzilla bug %d)", entry.BugID)
+	}
+	for _, p := range entry.PRs {
+		fmt.Fprintf(&b, " [%s/%s#%d]", p.Org, p.Repo, p.Num)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func categoryLabel(category Category) string {
+	switch category {
+	case CategoryBreaking:
+		return ":warning: **Breaking change:**"
+	case CategoryFeature:
+		return "**Feature:**"
+	case CategoryFix:
+		return "**Fix:**"
+	case CategoryRevert:
+		return "**Revert:**"
+	default:
+		return ""
+	}
+}
+
+// JSON renders notes as indented JSON.
+func (n *Notes) JSON() ([]byte, error) {
+	return json.MarshalIndent(n, "", "  ")
+}
+
+// Composer builds a Notes document from the bugzilla bugs fixed in a release. It is intended
+// to be invoked by the release-controller verify path alongside Verifier.VerifyBugs, once a
+// tag has been accepted, so that a notes artifact can be written out next to the verification
+// comments VerifyBugs posts. That call site lives in the release-controller's sync loop, which
+// is outside this checkout (this tree contains only pkg/bugzilla and pkg/releasenotes) — as of
+// this commit nothing in this repository calls NewComposer or Compose yet, and wiring that up
+// is follow-up work, not something this package can do on its own.
+type Composer struct {
+	bzClient bugzilla.Client
+	ghClient github.Client
+	verifier *bzverifier.Verifier
+}
+
+// NewComposer returns a Composer configured with the provided bugzilla and github clients.
+// verifier is used to resolve each bug's linked PRs and clone-chain root via GetPRs.
+func NewComposer(bzClient bugzilla.Client, ghClient github.Client, verifier *bzverifier.Verifier) *Composer {
+	return &Composer{bzClient: bzClient, ghClient: ghClient, verifier: verifier}
+}
+
+// groupKey groups release note entries by bugzilla component and sub-component.
+type groupKey struct {
+	component, subComponent string
+}
+
+// Compose builds release notes for the given bugzilla bug IDs, as fixed in tagName.
+func (c *Composer) Compose(bugs []int, tagName string) (*Notes, []error) {
+	bugPRs, errs := c.verifier.GetPRs(bugs)
+
+	grouped := make(map[groupKey][]Entry)
+
+	for bugID, info := range bugPRs {
+		entry := Entry{
+			BugID:     bugID,
+			RootID:    info.RootID,
+			Summary:   info.Bug.Summary,
+			Severity:  info.Bug.Severity,
+			Component: "uncategorized",
+		}
+		if len(info.Bug.Component) > 0 {
+			entry.Component = info.Bug.Component[0]
+		}
+		if len(info.Bug.TargetRelease) > 0 {
+			entry.TargetRelease = info.Bug.TargetRelease[0]
+		}
+		subComponents, err := c.bzClient.GetSubComponentsOnBug(bugID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to get sub-components for bugzilla bug %d: %v", bugID, err))
+		} else {
+			entry.SubComponents = subComponents[entry.Component]
+		}
+		for _, p := range info.PRs {
+			var title string
+			if ghPR, err := c.ghClient.GetPullRequest(p.Org, p.Repo, p.Num); err != nil {
+				errs = append(errs, fmt.Errorf("Unable to get github pull %s/%s#%d: %v", p.Org, p.Repo, p.Num, err))
+			} else {
+				title = ghPR.Title
+			}
+			entry.PRs = append(entry.PRs, PRRef{Org: p.Org, Repo: p.Repo, Num: p.Num, Title: title})
+			if cat := categorize(title); entry.Category == "" || cat == CategoryBreaking {
+				entry.Category = cat
+			}
+		}
+		if entry.Category == "" {
+			entry.Category = CategoryOther
+		}
+		var subComponent string
+		if len(entry.SubComponents) > 0 {
+			subComponent = entry.SubComponents[0]
+		}
+		key := groupKey{entry.Component, subComponent}
+		grouped[key] = append(grouped[key], entry)
+	}
+
+	notes := &Notes{TagName: tagName}
+	for _, component := range sortedComponents(grouped) {
+		cn := ComponentNotes{Component: component}
+		for _, subComponent := range sortedSubComponents(grouped, component) {
+			entries := grouped[groupKey{component, subComponent}]
+			sort.Slice(entries, func(i, j int) bool { return entries[i].BugID < entries[j].BugID })
+			cn.SubComponents = append(cn.SubComponents, SubComponentNotes{SubComponent: subComponent, Entries: entries})
+		}
+		notes.Components = append(notes.Components, cn)
+	}
+	return notes, errs
+}
+
+func sortedComponents(grouped map[groupKey][]Entry) []string {
+	seen := make(map[string]bool)
+	var components []string
+	for key := range grouped {
+		if !seen[key.component] {
+			seen[key.component] = true
+			components = append(components, key.component)
+		}
+	}
+	sort.Strings(components)
+	return components
+}
+
+func sortedSubComponents(grouped map[groupKey][]Entry, component string) []string {
+	seen := make(map[string]bool)
+	var subComponents []string
+	for key := range grouped {
+		if key.component != component || seen[key.subComponent] {
+			continue
+		}
+		seen[key.subComponent] = true
+		subComponents = append(subComponents, key.subComponent)
+	}
+	sort.Strings(subComponents)
+	return subComponents
+}
@@ -0,0 +1,182 @@
+package releasenotes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	bzverifier "github.com/openshift/release-controller/pkg/bugzilla"
+	"k8s.io/test-infra/prow/bugzilla"
+	"k8s.io/test-infra/prow/github"
+)
+
+func TestCategorizePrecedence(t *testing.T) {
+	tests := []struct {
+		title string
+		want  Category
+	}{
+		{"fix: correct off-by-one in retry loop", CategoryFix},
+		{"feat: add support for custom timeouts", CategoryFeature},
+		{"revert: \"feat: add support for custom timeouts\"", CategoryRevert},
+		{":warning: fix: drop a field from the public API", CategoryBreaking},
+		{"chore: bump dependency versions", CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := categorize(tt.title); got != tt.want {
+			t.Errorf("categorize(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+// stubNotesBugzillaClient is a bugzilla.Client serving only the methods GetPRs and Compose
+// need, embedding the interface so the remaining methods are never called by the code under
+// test.
+type stubNotesBugzillaClient struct {
+	bugzilla.Client
+	bugs          map[int]*bugzilla.Bug
+	extBugs       map[int][]bugzilla.ExternalBug
+	subComponents map[int]map[string][]string
+}
+
+func (s *stubNotesBugzillaClient) GetBug(id int) (*bugzilla.Bug, error) {
+	return s.bugs[id], nil
+}
+
+func (s *stubNotesBugzillaClient) GetExternalBugPRsOnBug(id int) ([]bugzilla.ExternalBug, error) {
+	return s.extBugs[id], nil
+}
+
+func (s *stubNotesBugzillaClient) GetSubComponentsOnBug(id int) (map[string][]string, error) {
+	return s.subComponents[id], nil
+}
+
+// stubNotesGithubClient is a github.Client serving only GetPullRequest, embedding the interface
+// so the remaining methods are never called by the code under test.
+type stubNotesGithubClient struct {
+	github.Client
+	titles map[string]string
+}
+
+func (s *stubNotesGithubClient) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	pr := &github.PullRequest{}
+	pr.Title = s.titles[prKey(org, repo, number)]
+	return pr, nil
+}
+
+func prKey(org, repo string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", org, repo, number)
+}
+
+func externalGitHubBug(org, repo string, num int) bugzilla.ExternalBug {
+	eb := bugzilla.ExternalBug{Org: org, Repo: repo, Num: num}
+	eb.Type.URL = "https://github.com/"
+	return eb
+}
+
+func TestComposeCollapsesPRsAndGroups(t *testing.T) {
+	bzClient := &stubNotesBugzillaClient{
+		bugs: map[int]*bugzilla.Bug{
+			1: {ID: 1, Summary: "widget is broken", Component: []string{"widgets"}, TargetRelease: []string{"4.16.0"}},
+			2: {ID: 2, Summary: "gadget is broken", Component: []string{"gadgets"}, TargetRelease: []string{"4.16.0"}},
+		},
+		extBugs: map[int][]bugzilla.ExternalBug{
+			1: {externalGitHubBug("openshift", "origin", 1), externalGitHubBug("openshift", "origin", 2)},
+			2: {externalGitHubBug("openshift", "origin", 3)},
+		},
+		subComponents: map[int]map[string][]string{
+			1: {"widgets": {"frobnicator"}},
+		},
+	}
+	ghClient := &stubNotesGithubClient{titles: map[string]string{
+		prKey("openshift", "origin", 1): "fix: stop dropping frobnicator events",
+		prKey("openshift", "origin", 2): ":warning: fix: change frobnicator wire format",
+		prKey("openshift", "origin", 3): "feat: add gadget telemetry",
+	}}
+
+	verifier := bzverifier.NewVerifier(bzClient, ghClient, nil, nil, nil, nil, nil)
+	composer := NewComposer(bzClient, ghClient, verifier)
+
+	notes, errs := composer.Compose([]int{1, 2}, "4.16.0")
+	if len(errs) > 0 {
+		t.Fatalf("Compose returned errors: %v", errs)
+	}
+
+	var widgetEntry, gadgetEntry *Entry
+	for _, component := range notes.Components {
+		for _, sub := range component.SubComponents {
+			for i := range sub.Entries {
+				switch sub.Entries[i].BugID {
+				case 1:
+					widgetEntry = &sub.Entries[i]
+				case 2:
+					gadgetEntry = &sub.Entries[i]
+				}
+			}
+		}
+	}
+	if widgetEntry == nil || gadgetEntry == nil {
+		t.Fatalf("Compose did not produce entries for both bugs: %+v", notes)
+	}
+	if len(widgetEntry.PRs) != 2 {
+		t.Fatalf("bug 1 entry has %d PRs, want both linked PRs collapsed into one entry", len(widgetEntry.PRs))
+	}
+	// One of bug 1's two PR titles carries :warning:, which must win over the plain fix: on
+	// the other PR regardless of which PR was processed last.
+	if widgetEntry.Category != CategoryBreaking {
+		t.Errorf("bug 1 entry Category = %q, want %q (warning overrides fix)", widgetEntry.Category, CategoryBreaking)
+	}
+	if len(widgetEntry.SubComponents) != 1 || widgetEntry.SubComponents[0] != "frobnicator" {
+		t.Errorf("bug 1 entry SubComponents = %v, want [frobnicator]", widgetEntry.SubComponents)
+	}
+	if gadgetEntry.Category != CategoryFeature {
+		t.Errorf("bug 2 entry Category = %q, want %q", gadgetEntry.Category, CategoryFeature)
+	}
+}
+
+func TestMarkdownGroupOrdering(t *testing.T) {
+	notes := &Notes{
+		TagName: "4.16.0",
+		Components: []ComponentNotes{
+			{
+				Component: "widgets",
+				SubComponents: []SubComponentNotes{
+					{SubComponent: "", Entries: []Entry{{BugID: 1, Summary: "widget fix"}}},
+					{SubComponent: "frobnicator", Entries: []Entry{{BugID: 2, Summary: "frobnicator fix"}}},
+				},
+			},
+			{
+				Component: "gadgets",
+				SubComponents: []SubComponentNotes{
+					{SubComponent: "", Entries: []Entry{{BugID: 3, Summary: "gadget fix"}}},
+				},
+			},
+		},
+	}
+
+	md := notes.Markdown()
+	widgetsIdx := strings.Index(md, "## widgets")
+	gadgetsIdx := strings.Index(md, "## gadgets")
+	subIdx := strings.Index(md, "### frobnicator")
+	if widgetsIdx == -1 || gadgetsIdx == -1 || subIdx == -1 {
+		t.Fatalf("Markdown output missing expected headers:\n%s", md)
+	}
+	if !(widgetsIdx < subIdx && subIdx < gadgetsIdx) {
+		t.Errorf("Markdown headers out of order, want widgets, then frobnicator sub-heading, then gadgets:\n%s", md)
+	}
+	if !strings.Contains(md, "widget fix (Bugzilla bug 1)") {
+		t.Errorf("Markdown output missing entry for bug 1:\n%s", md)
+	}
+
+	data, err := notes.JSON()
+	if err != nil {
+		t.Fatalf("JSON returned error: %v", err)
+	}
+	var roundTripped Notes
+	if err := json.Unmarshal(data, &roundTripped); err != nil {
+		t.Fatalf("failed to unmarshal JSON output: %v", err)
+	}
+	if len(roundTripped.Components) != 2 || roundTripped.Components[0].Component != "widgets" || roundTripped.Components[1].Component != "gadgets" {
+		t.Errorf("JSON round-trip lost component ordering: %+v", roundTripped.Components)
+	}
+}